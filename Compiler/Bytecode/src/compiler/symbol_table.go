@@ -2,7 +2,12 @@ package compiler
 
 type SymbolScope string
 
-const GlobalScope SymbolScope = "GLOBAL"
+const (
+	GlobalScope  SymbolScope = "GLOBAL"
+	LocalScope   SymbolScope = "LOCAL"
+	BuiltinScope SymbolScope = "BUILTIN"
+	FreeScope    SymbolScope = "FREE"
+)
 
 type Symbol struct {
 	Name  string
@@ -10,29 +15,86 @@ type Symbol struct {
 	Index int
 }
 
+/*
+SymbolTable 按作用域嵌套组织符号，outer 指向外层作用域。
+FreeSymbols 记录了在当前作用域中被闭包引用、但定义在更外层作用域的符号，
+编译器据此生成 OpGetFree/OpClosure 指令。
+*/
 type SymbolTable struct {
+	outer *SymbolTable
+
+	FreeSymbols []Symbol
+
 	store          map[string]Symbol
 	numDefinitions int
 }
 
 func NewSymbolTable() *SymbolTable {
 	return &SymbolTable{
-		store:          make(map[string]Symbol),
-		numDefinitions: 0,
+		store:       make(map[string]Symbol),
+		FreeSymbols: []Symbol{},
 	}
 }
 
+// NewEnclosedSymbolTable 创建一个以 outer 为外层作用域的 SymbolTable，
+// 用于编译嵌套的函数字面量。
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.outer = outer
+	return s
+}
+
+// Define 在当前作用域中定义一个符号，最外层是 GlobalScope，其余都是 LocalScope。
 func (s *SymbolTable) Define(name string) Symbol {
-	s.store[name] = Symbol{
-		Name:  name,
-		Scope: GlobalScope,
-		Index: s.numDefinitions,
+	symbol := Symbol{Name: name, Index: s.numDefinitions}
+	if s.outer == nil {
+		symbol.Scope = GlobalScope
+	} else {
+		symbol.Scope = LocalScope
 	}
+	s.store[name] = symbol
 	s.numDefinitions++
-	return s.store[name]
+	return symbol
+}
+
+// DefineBuiltin 定义一个内置函数，index 是它在 object.Builtins 中的下标。
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
+	s.store[name] = symbol
+	return symbol
 }
 
+// DefineFree 将一个在外层作用域解析到的符号记录为当前作用域的自由变量，
+// 返回的 Symbol 其 Scope 固定为 FreeScope，Index 指向 FreeSymbols 中的位置。
+func (s *SymbolTable) DefineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1}
+	symbol.Scope = FreeScope
+
+	s.store[original.Name] = symbol
+	return symbol
+}
+
+/*
+Resolve 在当前作用域查找 name，找不到则向外层作用域递归查找。
+如果符号是在某个外层作用域中解析到的，并且既不是 GlobalScope 也不是
+BuiltinScope（即它是某个外层函数的局部变量或自由变量），就把它提升为
+当前作用域的自由变量，从而支持闭包跨多层函数访问外层局部变量。
+*/
 func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 	obj, ok := s.store[name]
+	if !ok && s.outer != nil {
+		obj, ok = s.outer.Resolve(name)
+		if !ok {
+			return obj, ok
+		}
+		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+			return obj, ok
+		}
+
+		free := s.DefineFree(obj)
+		return free, true
+	}
 	return obj, ok
 }
@@ -22,7 +22,8 @@ type Call struct {
 	Args          interface{}
 	Reply         interface{}
 	Error         error
-	Done          chan *Call // 当调用结束时，会调用 call.done() 通知调用方。
+	Done          chan *Call      // 当调用结束时，会调用 call.done() 通知调用方。
+	Ctx           context.Context // 调用方传入的 context，携带截止时间和出站元数据
 }
 
 func (call *Call) done() {
@@ -51,6 +52,14 @@ type Client struct {
 	closing  bool             // 一般是用户主动调用 Close 方法进行关闭
 	shutdown bool             // 一般是有错误发生 server 要求关闭
 	option   *Option
+	plugins  PluginContainer // 请求/响应收发路径上的插件扩展点
+	pool     *ClientPool     // 不为 nil 时，表示该 Client 借自连接池，Close 时应当归还而不是直接断开
+	poolKey  poolKey
+}
+
+// AddPlugin 为 client 注册一个插件，常用于鉴权、监控等横切关注点
+func (client *Client) AddPlugin(plugin Plugin) {
+	client.plugins.Add(plugin)
 }
 
 /*
@@ -69,7 +78,7 @@ func NewClient(conn net.Conn, option *Option) (*Client, error) {
 		_ = conn.Close()
 		return nil, err
 	}
-	return newClientCodec(codecFunc(conn), option), nil
+	return newClientCodec(codecFunc(conn, option.Framing), option), nil
 }
 
 // newClientCodec 创建一个子协程调用 receive() 接收响应。
@@ -79,6 +88,7 @@ func newClientCodec(cc codec.Codec, option *Option) *Client {
 		seq:     0,
 		pending: make(map[uint64]*Call),
 		option:  option,
+		plugins: NewPluginContainer(),
 	}
 	go client.receive()
 	return client
@@ -92,13 +102,18 @@ func parseOptions(options ...*Option) (*Option, error) {
 	if len(options) != 1 {
 		return nil, errors.New("[parseOptions] number of options is more than 1")
 	}
-	opt := options[0]
+	// 拷贝一份再填默认值，不能直接改调用方传入的 *Option：ClientPool.Get
+	// 等路径会在多个 goroutine 间复用同一个 *Option，原地修改会产生数据竞争。
+	opt := *options[0]
 	// magic Number 必须一致
 	opt.MagicNumber = DefaultOption.MagicNumber
 	if opt.CodecType == "" {
 		opt.CodecType = DefaultOption.CodecType
 	}
-	return opt, nil
+	if opt.Framing == "" {
+		opt.Framing = DefaultOption.Framing
+	}
+	return &opt, nil
 }
 
 /*
@@ -116,6 +131,10 @@ func dialTimeout(clientFunc newClientFunc, network, address string, opts ...*Opt
 	if err != nil {
 		return nil, err
 	}
+	// 连接建立前先交给全局插件做一次前置检查（如白名单、限流）
+	if err := globalPlugins.DoPreConnect(network, address); err != nil {
+		return nil, err
+	}
 	// DialTimeout 采用了超时
 	conn, err := net.DialTimeout(network, address, options.ConnectTimeout)
 	if err != nil {
@@ -157,20 +176,28 @@ func Dial(network, address string, opts ...*Option) (*Client, error) {
 	Client 的发送功能
 */
 // Call 函数
-func (client *Client) Call(ctx context.Context, serviceMethod string, args, reply interface{}) error {
-	call := client.Go(serviceMethod, args, reply, make(chan *Call, 1))
+func (client *Client) Call(ctx context.Context, serviceMethod string, args, reply interface{}) (err error) {
+	call := client.goWithContext(ctx, serviceMethod, args, reply, make(chan *Call, 1))
+	defer func() { client.plugins.DoPostCall(call.Seq, serviceMethod, reply, err) }()
 
 	select {
 	case <-ctx.Done():
 		client.removeCall(call.Seq)
-		return errors.New("rpc client: call failed: " + ctx.Err().Error())
+		err = errors.New("rpc client: call failed: " + ctx.Err().Error())
+		return err
 	case call = <-call.Done:
-		return call.Error
+		err = call.Error
+		return err
 	}
 }
 
-// Go 有一个异步接口，返回 call 实例
+// Go 有一个异步接口，返回 call 实例，不绑定 context，因此请求不会携带
+// 截止时间和 WithOutgoingMetadata 设置的元数据，等价于 context.Background()
 func (client *Client) Go(serviceMethod string, args, reply interface{}, done chan *Call) *Call {
+	return client.goWithContext(context.Background(), serviceMethod, args, reply, done)
+}
+
+func (client *Client) goWithContext(ctx context.Context, serviceMethod string, args, reply interface{}, done chan *Call) *Call {
 	if done == nil {
 		done = make(chan *Call, 10)
 	} else if cap(done) == 0 {
@@ -182,6 +209,7 @@ func (client *Client) Go(serviceMethod string, args, reply interface{}, done cha
 		Args:          args,
 		Reply:         reply,
 		Done:          done,
+		Ctx:           ctx,
 	}
 	client.send(call)
 	return call
@@ -200,10 +228,38 @@ func (client *Client) send(call *Call) {
 		return
 	}
 
+	// seq 分配完成后才能触发 PreCall：插件（如 MetricsPlugin）需要靠它
+	// 区分并发的同方法调用，在 registerCall 之前是拿不到的。
+	if err := client.plugins.DoPreCall(seq, call.ServiceMethod, call.Args); err != nil {
+		call := client.removeCall(seq)
+		if call != nil {
+			call.Error = err
+			call.done()
+		}
+		return
+	}
+
 	// 准备 request header
 	client.header.ServiceMethod = call.ServiceMethod
 	client.header.Seq = seq
 	client.header.Error = ""
+	client.header.Token = ""
+	client.header.Metadata = outgoingMetadataFromContext(call.Ctx)
+	client.header.Deadline = time.Time{}
+	if call.Ctx != nil {
+		if deadline, ok := call.Ctx.Deadline(); ok {
+			client.header.Deadline = deadline
+		}
+	}
+
+	if err := client.plugins.DoPreWriteRequest(&client.header, call.Args); err != nil {
+		call := client.removeCall(seq)
+		if call != nil {
+			call.Error = err
+			call.done()
+		}
+		return
+	}
 
 	// 编码发送
 	if err := client.cc.Write(&client.header, call.Args); err != nil {
@@ -226,6 +282,9 @@ func (client *Client) receive() {
 		if err = client.cc.ReadHeader(&header); err != nil {
 			break
 		}
+		if err = client.plugins.DoPostReadResponse(&header); err != nil {
+			break
+		}
 		call := client.removeCall(header.Seq)
 		switch {
 		case call == nil:
@@ -286,6 +345,10 @@ func (client *Client) terminateCalls(err error) {
 		call.Error = err
 		call.done()
 	}
+	// 发生错误的连接不应该再被连接池当作健康连接复用
+	if client.pool != nil {
+		client.pool.evict(client)
+	}
 }
 
 /*
@@ -294,8 +357,23 @@ func (client *Client) terminateCalls(err error) {
 
 var ErrShutdown = errors.New("connection is shutdown")
 
-// Close the connection
+// Close 关闭连接。如果该 Client 借自连接池，则优先归还给连接池，
+// 真正断开连接的时机交由连接池按照 PoolOptions 决定。
 func (client *Client) Close() error {
+	client.mu.Lock()
+	pool := client.pool
+	closing := client.closing
+	client.mu.Unlock()
+
+	if pool != nil && !closing {
+		pool.put(client)
+		return nil
+	}
+	return client.closeConn()
+}
+
+// closeConn 真正关闭底层连接，不经过连接池
+func (client *Client) closeConn() error {
 	client.mu.Lock()
 	defer client.mu.Unlock()
 
@@ -335,21 +413,27 @@ func DialHTTP(network, address string, opts ...*Option) (*Client, error) {
 	return dialTimeout(NewHTTPClient, network, address, opts...)
 }
 
+// defaultClientPool 让 XDial 开箱即用地复用连接，调用方也可以用 NewClientPool
+// 创建一个独立的连接池，并通过 pool.Get/client.Close 显式管理连接的借还。
+var defaultClientPool = NewClientPool(PoolOptions{})
+
 // XDial calls different func to connect to a RPC server
 // according to Lexer param rpcAddr
 // rpcAddr is a general format (protocol@addr) to represent a rpc server
 // eg, http@10.0.0.1:7001, tcp@10.0.0.1:9999, unix@/tmp/geerpc.sock
+//
+// XDial 底层通过 defaultClientPool 借还连接，相同 (protocol, addr, Option)
+// 的多次 XDial/Close 会透明地复用同一组连接，而不是每次都重新建立。
 func XDial(rpcAddr string, options ...*Option) (*Client, error) {
 	parts := strings.Split(rpcAddr, "@")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("[XDial] rpc client err: wrong format '%s', expect protocol@addr", rpcAddr)
 	}
 	protocol, addr := parts[0], parts[1]
-	switch protocol {
-	case "http":
-		return DialHTTP("tcp", addr, options...)
-	default:
-		// tcp unix or other transport protocol
-		return Dial(protocol, addr, options...)
+	var clientFunc newClientFunc = NewClient
+	network := protocol
+	if protocol == "http" {
+		clientFunc, network = NewHTTPClient, "tcp"
 	}
+	return defaultClientPool.Get(clientFunc, network, addr, options...)
 }
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"io"
+	"time"
+)
+
+/*
+Header 是一次 RPC 调用的消息头。
+ServiceMethod 格式 "Service.Method"，与 Go 内置的 net/rpc 保持一致。
+Seq 是请求的序号，也可以认为是某个请求的 ID，用来区分不同的请求。
+Error 只有在服务端处理出错时才会设置。
+Token 由客户端的 AuthFunc 插件注入，服务端可以据此校验调用方身份，
+留空表示调用方没有启用鉴权插件。
+Metadata 携带调用方通过 geerpc.WithOutgoingMetadata 注册的自定义键值对
+（如 trace ID），服务端通过 geerpc.IncomingMetadata 读取，留空表示没有携带。
+Deadline 是调用方 context.Context 的截止时间，零值表示调用没有设置超时。
+*/
+type Header struct {
+	ServiceMethod string
+	Seq           uint64
+	Error         string
+	Token         string
+	Metadata      map[string]string
+	Deadline      time.Time
+}
+
+// Codec 对消息体进行编解码的接口，抽象出接口是为了实现不同的 Codec 实例
+type Codec interface {
+	io.Closer
+	ReadHeader(*Header) error
+	ReadBody(interface{}) error
+	Write(*Header, interface{}) error
+}
+
+// NewCodecFunc 根据底层连接和协商好的 Framing 创建一个 Codec 实例
+type NewCodecFunc func(conn io.ReadWriteCloser, framing FramingType) Codec
+
+type Type string
+
+const (
+	GobType      Type = "application/gob"
+	JsonType     Type = "application/json" // not implemented
+	ProtobufType Type = "application/protobuf"
+)
+
+// NewCodecFuncMap 通过 Codec 的 Type 得到构造函数，从而创建 Codec 实例
+var NewCodecFuncMap map[Type]NewCodecFunc
+
+func init() {
+	NewCodecFuncMap = make(map[Type]NewCodecFunc)
+	NewCodecFuncMap[GobType] = NewGobCodec
+	NewCodecFuncMap[ProtobufType] = NewProtobufCodec
+}
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+/*
+FramingType 独立于具体的编码方式，描述消息的边界如何界定。
+RawFraming 依赖编码器自身提供消息边界（例如 gob.Decoder 按调用顺序读取）；
+LengthPrefixedFraming 在每个消息前写入一个 uvarint 长度，编码方式本身不需要
+自带消息边界，这样纯二进制的编码（如 protobuf）也能安全地复用同一条连接。
+*/
+type FramingType string
+
+const (
+	RawFraming            FramingType = "raw"
+	LengthPrefixedFraming FramingType = "length-prefixed"
+)
+
+// maxFrameSize 限制 readFrame 单帧能分配的最大字节数，防止对端（或者损坏的流）
+// 发来一个离谱的长度前缀时，make([]byte, length) 直接把进程 OOM 或者 panic。
+const maxFrameSize = 64 << 20 // 64MB，足够覆盖正常的 RPC 消息体
+
+// writeFrame 写入一个 uvarint 长度前缀，随后写入 data 本身
+func writeFrame(w *bufio.Writer, data []byte) error {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, uint64(len(data)))
+	if _, err := w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame 读取一个 uvarint 长度前缀，并返回其后对应长度的 data。
+// 长度前缀来自对端，分配前必须先校验上限，否则一个损坏或恶意的长度
+// （例如 uvarint 编码的 math.MaxUint64）会让 make([]byte, length) 直接 panic。
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxFrameSize {
+		return nil, fmt.Errorf("[readFrame] rpc codec: frame size %d exceeds max %d", length, maxFrameSize)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"io"
+	"log"
+)
+
+/*
+GobCodec 基于 gob 实现消息的编解码。
+framing 为 RawFraming 时，直接依赖 gob.Decoder/Encoder 按调用顺序读写，
+由 gob 自身的流式边界区分消息；framing 为 LengthPrefixedFraming 时，
+改为先把 Header/Body 分别 gob 编码到内存缓冲区，再以 uvarint 长度前缀写入连接，
+使得同一条连接上可以安全地插入非 gob 编码的消息。
+*/
+type GobCodec struct {
+	conn    io.ReadWriteCloser
+	buf     *bufio.Writer // 防止阻塞而创建的带缓冲的 Writer
+	r       *bufio.Reader
+	dec     *gob.Decoder
+	enc     *gob.Encoder
+	framing FramingType
+}
+
+var _ Codec = (*GobCodec)(nil)
+
+// NewGobCodec 创建一个 GobCodec 实例
+func NewGobCodec(conn io.ReadWriteCloser, framing FramingType) Codec {
+	if framing == "" {
+		framing = RawFraming
+	}
+	buf := bufio.NewWriter(conn)
+	r := bufio.NewReader(conn)
+	return &GobCodec{
+		conn:    conn,
+		buf:     buf,
+		r:       r,
+		dec:     gob.NewDecoder(r),
+		enc:     gob.NewEncoder(buf),
+		framing: framing,
+	}
+}
+
+func (c *GobCodec) ReadHeader(h *Header) error {
+	if c.framing == RawFraming {
+		return c.dec.Decode(h)
+	}
+	data, err := readFrame(c.r)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(h)
+}
+
+func (c *GobCodec) ReadBody(body interface{}) error {
+	if c.framing == RawFraming {
+		return c.dec.Decode(body)
+	}
+	data, err := readFrame(c.r)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return nil
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(body)
+}
+
+func (c *GobCodec) Write(h *Header, body interface{}) (err error) {
+	defer func() {
+		_ = c.buf.Flush()
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
+
+	if c.framing == RawFraming {
+		if err := c.enc.Encode(h); err != nil {
+			log.Println("[Write] rpc codec: gob error encoding header:", err)
+			return err
+		}
+		if err := c.enc.Encode(body); err != nil {
+			log.Println("[Write] rpc codec: gob error encoding body:", err)
+			return err
+		}
+		return nil
+	}
+
+	var headerBuf bytes.Buffer
+	if err := gob.NewEncoder(&headerBuf).Encode(h); err != nil {
+		log.Println("[Write] rpc codec: gob error encoding header:", err)
+		return err
+	}
+	if err := writeFrame(c.buf, headerBuf.Bytes()); err != nil {
+		return err
+	}
+
+	var bodyBuf bytes.Buffer
+	if body != nil {
+		if err := gob.NewEncoder(&bodyBuf).Encode(body); err != nil {
+			log.Println("[Write] rpc codec: gob error encoding body:", err)
+			return err
+		}
+	}
+	return writeFrame(c.buf, bodyBuf.Bytes())
+}
+
+func (c *GobCodec) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,49 @@
+package geerpc
+
+import (
+	"context"
+	"geerpc/codec"
+)
+
+/*
+metadata.go 负责把调用方 context.Context 中携带的截止时间和自定义键值对
+跨连接传递给服务端：客户端在 send 时把它们写进 codec.Header，服务端的
+处理循环（server.go）读取 Header 后通过 newIncomingContext 还原出一个
+新的、会在截止时间到达时自动取消的 context，交给对应的 handler 使用。
+*/
+
+type outgoingMetadataKey struct{}
+
+type incomingMetadataKey struct{}
+
+// WithOutgoingMetadata 把 md 绑定到 ctx 上，client.Call 在发送请求时会把它
+// 写入 codec.Header.Metadata 一并发送给服务端。
+func WithOutgoingMetadata(ctx context.Context, md map[string]string) context.Context {
+	return context.WithValue(ctx, outgoingMetadataKey{}, md)
+}
+
+// outgoingMetadataFromContext 取出调用方通过 WithOutgoingMetadata 注册的元数据
+func outgoingMetadataFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	md, _ := ctx.Value(outgoingMetadataKey{}).(map[string]string)
+	return md
+}
+
+// IncomingMetadata 在服务端 handler 中调用，取出调用方传递过来的元数据
+func IncomingMetadata(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(incomingMetadataKey{}).(map[string]string)
+	return md
+}
+
+// newIncomingContext 由服务端的处理循环在读取到一次请求的 Header 后调用，
+// 把 Header.Metadata 放入 handler 使用的 context，并让 Header.Deadline
+// 驱动 context 的取消：到期后 ctx.Done() 会被关闭，handler 应当据此中止处理。
+func newIncomingContext(parent context.Context, header *codec.Header) (context.Context, context.CancelFunc) {
+	ctx := context.WithValue(parent, incomingMetadataKey{}, header.Metadata)
+	if header.Deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, header.Deadline)
+}
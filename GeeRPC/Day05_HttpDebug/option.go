@@ -0,0 +1,33 @@
+package geerpc
+
+import (
+	"geerpc/codec"
+	"time"
+)
+
+// MagicNumber geerpc 的请求标志，用来验证这是一个 geerpc 的请求
+const MagicNumber = 0x3bef5c
+
+/*
+Option 是客户端与服务端握手阶段交换的协议选项，采用 JSON 编码传输，
+后续的 Header 和 Body 才按照 CodecType 指定的编解码方式进行传输。
+Framing 独立于 CodecType 描述消息边界如何界定：同一种编码方式可以搭配不同的
+Framing（例如 gob 既可以依赖自身的流式边界，也可以像 protobuf 一样采用
+长度前缀），两者在握手时各自声明、互不影响。
+*/
+type Option struct {
+	MagicNumber    int
+	CodecType      codec.Type
+	Framing        codec.FramingType
+	ConnectTimeout time.Duration // 0 表示不设置超时
+	HandleTimeout  time.Duration
+	Pool           PoolOptions // 连接池相关配置，零值字段沿用 ClientPool 构造时的默认值
+}
+
+// DefaultOption 默认使用 gob 编码 + 流式边界，与此前版本保持行为一致
+var DefaultOption = &Option{
+	MagicNumber:    MagicNumber,
+	CodecType:      codec.GobType,
+	Framing:        codec.RawFraming,
+	ConnectTimeout: time.Second * 10,
+}
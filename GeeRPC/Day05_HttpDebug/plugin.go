@@ -0,0 +1,139 @@
+package geerpc
+
+import (
+	"geerpc/codec"
+	"sync"
+)
+
+/*
+Plugin 是插件系统的标记接口，一个插件可以按需实现下面任意一个或多个 Hook 接口，
+未实现的 Hook 会被 PluginContainer 自动忽略。这让 Call/Go 路径在不修改 codec
+的前提下，拥有了注入鉴权、监控等横切关注点的扩展点。
+*/
+type Plugin interface{}
+
+// PreConnectPlugin 在底层连接建立之前触发（dialTimeout 调用 net.DialTimeout 之前），
+// 只对通过 RegisterPlugin 注册的全局插件生效
+type PreConnectPlugin interface {
+	PreConnect(network, address string) error
+}
+
+// PreCallPlugin 在一次调用被分配 seq、真正发出之前触发，seq 与该次调用的
+// codec.Header.Seq 一致，可用于区分并发的同方法调用
+type PreCallPlugin interface {
+	PreCall(seq uint64, serviceMethod string, args interface{}) error
+}
+
+// PreWriteRequestPlugin 在请求的 Header/Body 被编码写入连接之前触发
+type PreWriteRequestPlugin interface {
+	PreWriteRequest(header *codec.Header, args interface{}) error
+}
+
+// PostReadResponsePlugin 在响应的 Header 被读取之后、Body 被读取之前触发
+type PostReadResponsePlugin interface {
+	PostReadResponse(header *codec.Header) error
+}
+
+// PostCallPlugin 在一次 Client.Call 结束（无论成功还是失败）之后触发，seq 与对应
+// PreCall 收到的 seq 相同，用于把一次调用的起止两端对应起来。
+// 通过 Client.Go 发起的异步调用只会触发 PreCall，不会触发 PostCall。
+type PostCallPlugin interface {
+	PostCall(seq uint64, serviceMethod string, reply interface{}, err error)
+}
+
+// PluginContainer 管理注册的插件，并依次触发实现了对应 Hook 的插件
+type PluginContainer interface {
+	Add(plugin Plugin)
+	DoPreConnect(network, address string) error
+	DoPreCall(seq uint64, serviceMethod string, args interface{}) error
+	DoPreWriteRequest(header *codec.Header, args interface{}) error
+	DoPostReadResponse(header *codec.Header) error
+	DoPostCall(seq uint64, serviceMethod string, reply interface{}, err error)
+}
+
+type pluginContainer struct {
+	mu      sync.RWMutex
+	plugins []Plugin
+}
+
+// NewPluginContainer 创建一个空的 PluginContainer
+func NewPluginContainer() PluginContainer {
+	return &pluginContainer{}
+}
+
+// globalPlugins 承载连接建立前触发的插件，此时 Client 实例尚未创建，
+// 因此无法使用 Client 自身持有的 PluginContainer。
+var globalPlugins = NewPluginContainer()
+
+// RegisterPlugin 注册一个全局插件，在 Dial/XDial 建立连接前触发 PreConnect
+func RegisterPlugin(plugin Plugin) {
+	globalPlugins.Add(plugin)
+}
+
+func (p *pluginContainer) Add(plugin Plugin) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.plugins = append(p.plugins, plugin)
+}
+
+func (p *pluginContainer) DoPreConnect(network, address string) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, pl := range p.plugins {
+		if plugin, ok := pl.(PreConnectPlugin); ok {
+			if err := plugin.PreConnect(network, address); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pluginContainer) DoPreCall(seq uint64, serviceMethod string, args interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, pl := range p.plugins {
+		if plugin, ok := pl.(PreCallPlugin); ok {
+			if err := plugin.PreCall(seq, serviceMethod, args); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pluginContainer) DoPreWriteRequest(header *codec.Header, args interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, pl := range p.plugins {
+		if plugin, ok := pl.(PreWriteRequestPlugin); ok {
+			if err := plugin.PreWriteRequest(header, args); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pluginContainer) DoPostReadResponse(header *codec.Header) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, pl := range p.plugins {
+		if plugin, ok := pl.(PostReadResponsePlugin); ok {
+			if err := plugin.PostReadResponse(header); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *pluginContainer) DoPostCall(seq uint64, serviceMethod string, reply interface{}, err error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, pl := range p.plugins {
+		if plugin, ok := pl.(PostCallPlugin); ok {
+			plugin.PostCall(seq, serviceMethod, reply, err)
+		}
+	}
+}
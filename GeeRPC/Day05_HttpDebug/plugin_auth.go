@@ -0,0 +1,49 @@
+package geerpc
+
+import (
+	"errors"
+	"geerpc/codec"
+)
+
+/*
+AuthFunc 校验调用方传入的 token 是否合法，由使用方提供具体的校验逻辑。
+*/
+type AuthFunc func(token string) bool
+
+// AuthPlugin 是一个内置插件，在请求发出前向 Header 注入 token，
+// 并在服务端通过同一个插件校验 Header 中携带的 token。
+type AuthPlugin struct {
+	Token string   // 客户端侧：写入请求 Header 的 token
+	Auth  AuthFunc // 服务端侧：校验请求 Header 中的 token
+}
+
+var _ PreWriteRequestPlugin = (*AuthPlugin)(nil)
+
+// NewAuthPlugin 创建一个客户端使用的 AuthPlugin，请求发出前自动注入 token
+func NewAuthPlugin(token string) *AuthPlugin {
+	return &AuthPlugin{Token: token}
+}
+
+// NewServerAuthPlugin 创建一个服务端使用的 AuthPlugin，按 auth 函数校验 token
+func NewServerAuthPlugin(auth AuthFunc) *AuthPlugin {
+	return &AuthPlugin{Auth: auth}
+}
+
+// PreWriteRequest 在客户端写请求前将 Token 注入 Header
+func (p *AuthPlugin) PreWriteRequest(header *codec.Header, _ interface{}) error {
+	if p.Token != "" {
+		header.Token = p.Token
+	}
+	return nil
+}
+
+// ValidateToken 服务端在收到请求后调用，校验 Header 中携带的 token 是否合法
+func (p *AuthPlugin) ValidateToken(header *codec.Header) error {
+	if p.Auth == nil {
+		return nil
+	}
+	if !p.Auth(header.Token) {
+		return errors.New("[ValidateToken] rpc server: invalid token")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package geerpc
+
+import (
+	"sync"
+	"time"
+)
+
+// MethodMetrics 记录单个 ServiceMethod 的调用延迟与错误计数
+type MethodMetrics struct {
+	Count      int64
+	ErrorCount int64
+	TotalNanos int64
+}
+
+/*
+MetricsPlugin 是一个内置插件，统计每个 ServiceMethod 的调用延迟和错误数，
+PreCall 记录起始时间，PostCall 计算耗时并累加到对应的 MethodMetrics 中。
+start 以 seq（即该次调用的 codec.Header.Seq）为键，而不是 ServiceMethod，
+因为同一个 Client 上并发调用同一个方法是常态，用 ServiceMethod 做键会让
+后发起的调用覆盖先发起的起始时间，算出错乱的耗时。
+*/
+type MetricsPlugin struct {
+	mu      sync.Mutex
+	start   sync.Map // seq -> time.Time
+	metrics map[string]*MethodMetrics
+}
+
+var _ PreCallPlugin = (*MetricsPlugin)(nil)
+var _ PostCallPlugin = (*MetricsPlugin)(nil)
+
+// NewMetricsPlugin 创建一个 MetricsPlugin 实例
+func NewMetricsPlugin() *MetricsPlugin {
+	return &MetricsPlugin{
+		metrics: make(map[string]*MethodMetrics),
+	}
+}
+
+// PreCall 记录本次调用的起始时间
+func (m *MetricsPlugin) PreCall(seq uint64, _ string, _ interface{}) error {
+	m.start.Store(seq, time.Now())
+	return nil
+}
+
+// PostCall 计算耗时，累加调用次数、错误次数和总耗时
+func (m *MetricsPlugin) PostCall(seq uint64, serviceMethod string, _ interface{}, err error) {
+	startVal, ok := m.start.LoadAndDelete(seq)
+	var elapsed time.Duration
+	if ok {
+		elapsed = time.Since(startVal.(time.Time))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm, ok := m.metrics[serviceMethod]
+	if !ok {
+		mm = &MethodMetrics{}
+		m.metrics[serviceMethod] = mm
+	}
+	mm.Count++
+	mm.TotalNanos += elapsed.Nanoseconds()
+	if err != nil {
+		mm.ErrorCount++
+	}
+}
+
+// Snapshot 返回当前各 ServiceMethod 的指标快照
+func (m *MetricsPlugin) Snapshot() map[string]MethodMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]MethodMetrics, len(m.metrics))
+	for method, mm := range m.metrics {
+		snapshot[method] = *mm
+	}
+	return snapshot
+}
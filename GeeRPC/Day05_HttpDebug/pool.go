@@ -0,0 +1,260 @@
+package geerpc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*
+PoolOptions 描述连接池的行为，挂在 Option 上随握手一起声明，使同一组
+(network, address, Option 指纹) 的调用方能够共享一组配置一致的连接。
+MaxIdle/MaxActive 为 0 表示不限制，IdleTimeout/HealthCheckInterval 为 0
+表示不主动过期、不启动后台健康检查协程。
+*/
+type PoolOptions struct {
+	MaxIdle             int
+	MaxActive           int
+	IdleTimeout         time.Duration
+	HealthCheckInterval time.Duration
+}
+
+// poolKey 区分不同的连接池，指纹由握手用的 Option 计算得到
+type poolKey struct {
+	network     string
+	address     string
+	fingerprint string
+}
+
+func newPoolKey(network, address string, opt *Option) poolKey {
+	return poolKey{
+		network:     network,
+		address:     address,
+		fingerprint: fmt.Sprintf("%d|%s|%s", opt.MagicNumber, opt.CodecType, opt.Framing),
+	}
+}
+
+type idleEntry struct {
+	client    *Client
+	idleSince time.Time
+}
+
+/*
+ClientPool 维护一组按 poolKey 分组的健康 Client，Get 优先复用空闲连接，
+空闲连接耗尽时才新建；Put 将使用完毕的连接放回空闲队列，供下次 Get 复用。
+
+opts 是构造时的默认 PoolOptions，调用方通过 Get 传入的 Option.Pool 里的
+非零字段会覆盖对应的默认值；每个 poolKey 第一次出现时把合并结果固定到
+keyOpts 里，后续同一个 key 下的 Get/Put/健康检查都按这份配置执行，避免
+共享同一个 key 的不同调用方各自生效、互相打架。
+*/
+type ClientPool struct {
+	opts PoolOptions
+
+	mu               sync.Mutex
+	idle             map[poolKey][]*idleEntry
+	active           map[poolKey]int
+	keyOpts          map[poolKey]PoolOptions
+	keyHealthStarted map[poolKey]bool
+
+	stopHealthCheck chan struct{}
+}
+
+// NewClientPool 创建一个连接池，opts 为零值时退化为不限制数量、不过期的策略
+func NewClientPool(opts PoolOptions) *ClientPool {
+	return &ClientPool{
+		opts:             opts,
+		idle:             make(map[poolKey][]*idleEntry),
+		active:           make(map[poolKey]int),
+		keyOpts:          make(map[poolKey]PoolOptions),
+		keyHealthStarted: make(map[poolKey]bool),
+		stopHealthCheck:  make(chan struct{}),
+	}
+}
+
+// keyPoolOptions 返回 key 对应的有效 PoolOptions：第一次见到这个 key 时，
+// 用 opt 中的非零字段覆盖 p.opts 对应的默认值并固定下来；之后同一个 key
+// 的所有调用都复用这份已经固定的配置。
+func (p *ClientPool) keyPoolOptions(key poolKey, opt PoolOptions) PoolOptions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if merged, ok := p.keyOpts[key]; ok {
+		return merged
+	}
+	merged := p.opts
+	if opt.MaxIdle != 0 {
+		merged.MaxIdle = opt.MaxIdle
+	}
+	if opt.MaxActive != 0 {
+		merged.MaxActive = opt.MaxActive
+	}
+	if opt.IdleTimeout != 0 {
+		merged.IdleTimeout = opt.IdleTimeout
+	}
+	if opt.HealthCheckInterval != 0 {
+		merged.HealthCheckInterval = opt.HealthCheckInterval
+	}
+	p.keyOpts[key] = merged
+	return merged
+}
+
+// ensureHealthCheck 为第一次见到的、声明了 HealthCheckInterval 的 key
+// 启动一个专属的后台协程定期清理过期空闲连接
+func (p *ClientPool) ensureHealthCheck(key poolKey, opts PoolOptions) {
+	if opts.HealthCheckInterval <= 0 {
+		return
+	}
+	p.mu.Lock()
+	if p.keyHealthStarted[key] {
+		p.mu.Unlock()
+		return
+	}
+	p.keyHealthStarted[key] = true
+	p.mu.Unlock()
+	go p.healthCheckKeyLoop(key, opts.HealthCheckInterval)
+}
+
+// Get 返回 network/address 对应的一个可用 Client，优先复用空闲连接
+func (p *ClientPool) Get(clientFunc newClientFunc, network, address string, opts ...*Option) (*Client, error) {
+	option, err := parseOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	key := newPoolKey(network, address, option)
+	keyOpts := p.keyPoolOptions(key, option.Pool)
+	p.ensureHealthCheck(key, keyOpts)
+
+	if client := p.popIdle(key, keyOpts); client != nil {
+		return client, nil
+	}
+
+	p.mu.Lock()
+	if keyOpts.MaxActive > 0 && p.active[key] >= keyOpts.MaxActive {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("[Get] rpc client pool: pool exhausted for %s %s", network, address)
+	}
+	p.active[key]++
+	p.mu.Unlock()
+
+	client, err := dialTimeout(clientFunc, network, address, option)
+	if err != nil {
+		p.mu.Lock()
+		p.active[key]--
+		p.mu.Unlock()
+		return nil, err
+	}
+	client.pool = p
+	client.poolKey = key
+	return client, nil
+}
+
+// popIdle 从空闲队列中取出一个仍然可用的连接，过期或不可用的连接会被直接丢弃
+func (p *ClientPool) popIdle(key poolKey, opts PoolOptions) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := p.idle[key]
+	for len(entries) > 0 {
+		n := len(entries)
+		entry := entries[n-1]
+		entries = entries[:n-1]
+		p.idle[key] = entries
+
+		expired := opts.IdleTimeout > 0 && time.Since(entry.idleSince) > opts.IdleTimeout
+		if expired || !entry.client.IsAvailable() {
+			// 这里已经持有 p.mu，必须用 closeConn 直接关底层连接；Close 会在
+			// client.pool 非空时尝试 put 回池子，重入 p.mu 造成自锁。
+			_ = entry.client.closeConn()
+			p.active[key]--
+			continue
+		}
+		return entry.client
+	}
+	return nil
+}
+
+// put 将 client 放回空闲队列，超过 MaxIdle 或已不可用的连接直接关闭并释放名额
+func (p *ClientPool) put(client *Client) {
+	key := client.poolKey
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	opts := p.keyOpts[key]
+	if !client.IsAvailable() || (opts.MaxIdle > 0 && len(p.idle[key]) >= opts.MaxIdle) {
+		p.active[key]--
+		_ = client.closeConn()
+		return
+	}
+	p.idle[key] = append(p.idle[key], &idleEntry{client: client, idleSince: time.Now()})
+}
+
+// evict 将发生错误的 client 从空闲队列和活跃计数中移除，避免之后被当作健康连接复用。
+// 调用方（terminateCalls）已经持有 client.mu，这里直接清空 client.pool，
+// 不再回去加锁：否则之后业务代码调用的 Close 会看到 pool 仍然非空，
+// 再次把这个已经失效的连接 put 回池子，既重复关闭又把 active[key] 多减一次。
+func (p *ClientPool) evict(client *Client) {
+	key := client.poolKey
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := p.idle[key]
+	for i, entry := range entries {
+		if entry.client == client {
+			p.idle[key] = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if p.active[key] > 0 {
+		p.active[key]--
+	}
+	client.pool = nil
+}
+
+// healthCheckKeyLoop 按 key 自己的 HealthCheckInterval 周期性清理该 key
+// 空闲队列中已经不可用的连接，由 ensureHealthCheck 为每个 key 启动一个实例
+func (p *ClientPool) healthCheckKeyLoop(key poolKey, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopHealthCheck:
+			return
+		case <-ticker.C:
+			p.sweepKey(key)
+		}
+	}
+}
+
+func (p *ClientPool) sweepKey(key poolKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	opts := p.keyOpts[key]
+	entries := p.idle[key]
+	alive := entries[:0]
+	for _, entry := range entries {
+		expired := opts.IdleTimeout > 0 && time.Since(entry.idleSince) > opts.IdleTimeout
+		if expired || !entry.client.IsAvailable() {
+			// 同 popIdle：sweepKey 已经持有 p.mu，只能 closeConn，不能 Close。
+			_ = entry.client.closeConn()
+			p.active[key]--
+			continue
+		}
+		alive = append(alive, entry)
+	}
+	p.idle[key] = alive
+}
+
+// Close 停止后台健康检查协程，并关闭所有空闲连接
+func (p *ClientPool) Close() error {
+	if p.stopHealthCheck != nil {
+		close(p.stopHealthCheck)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, entries := range p.idle {
+		for _, entry := range entries {
+			_ = entry.client.closeConn()
+		}
+		delete(p.idle, key)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+GeeRegistry 是一个简单的注册中心，提供以下功能：
+add a server and receive heartbeat to keep it alive.
+returns all alive servers.
+
+服务端启动后定期（默认比 defaultTimeout 少 1 min，即 4 min）向注册中心发送心跳，证明自己还活着。
+注册中心在返回服务列表时检查，清除超过 timeout（默认 5 min）未收到心跳的服务。
+*/
+type GeeRegistry struct {
+	timeout time.Duration
+	mu      sync.Mutex
+	servers map[string]*ServerItem
+}
+
+type ServerItem struct {
+	Addr  string
+	start time.Time
+}
+
+const (
+	defaultPath    = "/_geerpc_/registry"
+	defaultTimeout = 5 * time.Minute
+)
+
+// NewGeeRegistry 创建一个超时时间为 timeout 的注册中心实例
+func NewGeeRegistry(timeout time.Duration) *GeeRegistry {
+	return &GeeRegistry{
+		servers: make(map[string]*ServerItem),
+		timeout: timeout,
+	}
+}
+
+// DefaultGeeRegister 默认的注册中心实例，超时时间使用 defaultTimeout
+var DefaultGeeRegister = NewGeeRegistry(defaultTimeout)
+
+// putServer 添加或更新服务实例，若服务已存在，则更新 start 时间。
+func (r *GeeRegistry) putServer(addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.servers[addr]
+	if s == nil {
+		r.servers[addr] = &ServerItem{Addr: addr, start: time.Now()}
+	} else {
+		s.start = time.Now() // if exists, update start time to keep alive
+	}
+}
+
+// aliveServers 返回可用的服务列表，如果存在超时的服务，则删除。
+func (r *GeeRegistry) aliveServers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var alive []string
+	for addr, s := range r.servers {
+		if r.timeout == 0 || s.start.Add(r.timeout).After(time.Now()) {
+			alive = append(alive, addr)
+		} else {
+			delete(r.servers, addr)
+		}
+	}
+	sort.Strings(alive)
+	return alive
+}
+
+/*
+ServeHTTP 采用 HTTP 协议提供服务，且所有的有用信息都承载在 HTTP Header 中。
+Get: 返回所有可用的服务列表，通过自定义字段 X-Geerpc-Servers 承载。
+Post: 添加服务实例或发送心跳，通过自定义字段 X-Geerpc-Server 承载。
+*/
+func (r *GeeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "GET":
+		// keep it simple, servers are in the response header
+		w.Header().Set("X-Geerpc-Servers", strings.Join(r.aliveServers(), ","))
+	case "POST":
+		// keep it simple, server is in req.Header
+		addr := req.Header.Get("X-Geerpc-Server")
+		if addr == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.putServer(addr)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleHTTP 为 GeeRegistry 注册消息处理器
+func (r *GeeRegistry) HandleHTTP(registryPath string) {
+	http.Handle(registryPath, r)
+	log.Println("[HandleHTTP] rpc registry path:", registryPath)
+}
+
+// HandleHTTP 使用默认路径注册 DefaultGeeRegister
+func HandleHTTP() {
+	DefaultGeeRegister.HandleHTTP(defaultPath)
+}
+
+// Heartbeat 服务启动时定期向注册中心发送心跳，默认周期比注册中心设置的过期时间少 1 min
+func Heartbeat(registry, addr string, duration time.Duration) {
+	if duration == 0 {
+		// make sure there is enough time to send heart beat
+		// before it's removed from registry
+		duration = defaultTimeout - time.Minute
+	}
+	var err error
+	err = sendHeartbeat(registry, addr)
+	go func() {
+		t := time.NewTicker(duration)
+		for err == nil {
+			<-t.C
+			err = sendHeartbeat(registry, addr)
+		}
+	}()
+}
+
+func sendHeartbeat(registry, addr string) error {
+	log.Println(addr, "send heart beat to registry", registry)
+	httpClient := &http.Client{}
+	req, _ := http.NewRequest("POST", registry, nil)
+	req.Header.Set("X-Geerpc-Server", addr)
+	if _, err := httpClient.Do(req); err != nil {
+		log.Println("[sendHeartbeat] rpc server: heart beat err:", err)
+		return err
+	}
+	return nil
+}
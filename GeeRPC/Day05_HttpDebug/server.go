@@ -0,0 +1,201 @@
+package geerpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"geerpc/codec"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+)
+
+/*
+HTTP CONNECT 握手：客户端（NewHTTPClient）先发一个 CONNECT 请求到
+defaultRPCPath，服务端用 ServeHTTP 劫持这条 HTTP 连接、回一个 connected
+状态行，之后这条连接就切换成 geerpc 自己的协议，走 ServeConn 的逻辑。
+*/
+const (
+	connected      = "200 Connected to Gee RPC"
+	defaultRPCPath = "/_geerpc_"
+)
+
+/*
+Server 是一个精简的服务端：按 Header.ServiceMethod 分发到通过 Register 注册的
+HandlerFunc。完整的、基于反射自动从结构体方法生成 ServiceMethod 的服务注册
+（即常见教程里的 service.go）这里没有实现，调用方需要自己提供参数的构造函数
+和处理函数；Server 只负责协议层的握手、编解码、并发读写和鉴权，这正是
+AuthPlugin.ValidateToken 的服务端调用点。
+*/
+type Server struct {
+	mu       sync.RWMutex
+	handlers map[string]registeredHandler
+	auth     *AuthPlugin // 非 nil 时，每个请求都会先校验 Header.Token
+}
+
+// HandlerFunc 处理一次 RPC 调用，argv 由对应方法的 NewArgFunc 构造并填充
+type HandlerFunc func(ctx context.Context, argv interface{}) (reply interface{}, err error)
+
+// NewArgFunc 返回一个新的、可供 codec.ReadBody 反序列化的空参数实例
+type NewArgFunc func() interface{}
+
+type registeredHandler struct {
+	newArg  NewArgFunc
+	handler HandlerFunc
+}
+
+// NewServer 创建一个空的 Server，调用方通过 Register 注册方法后再用 ServeConn 处理连接
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]registeredHandler)}
+}
+
+// DefaultServer 是一个开箱即用的 Server 实例
+var DefaultServer = NewServer()
+
+// Register 注册一个 serviceMethod 对应的参数构造函数和处理函数
+func (server *Server) Register(serviceMethod string, newArg NewArgFunc, handler HandlerFunc) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.handlers[serviceMethod] = registeredHandler{newArg: newArg, handler: handler}
+}
+
+// UseAuth 为 server 配置鉴权插件，之后 ServeConn 会校验每个请求的 Token
+func (server *Server) UseAuth(auth *AuthPlugin) {
+	server.auth = auth
+}
+
+// ServeConn 在单个连接上完成握手，随后串行读取、并发处理请求，响应按处理完成的
+// 先后逐个加锁回写，
+// 直到连接关闭或者协议出错
+func (server *Server) ServeConn(conn io.ReadWriteCloser) {
+	defer func() { _ = conn.Close() }()
+
+	var opt Option
+	if err := json.NewDecoder(conn).Decode(&opt); err != nil {
+		log.Println("[ServeConn] rpc server: decode option error:", err)
+		return
+	}
+	if opt.MagicNumber != MagicNumber {
+		log.Printf("[ServeConn] rpc server: invalid magic number %x", opt.MagicNumber)
+		return
+	}
+	codecFunc := codec.NewCodecFuncMap[opt.CodecType]
+	if codecFunc == nil {
+		log.Printf("[ServeConn] rpc server: invalid codec type %s", opt.CodecType)
+		return
+	}
+	server.serveCodec(codecFunc(conn, opt.Framing))
+}
+
+// ServeHTTP 实现 http.Handler，只接受 CONNECT 方法：劫持底层连接、回写
+// connected 状态行后，把连接交给 ServeConn 按 geerpc 自己的协议处理。
+func (server *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "CONNECT" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = io.WriteString(w, "405 must CONNECT\n")
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		log.Println("[ServeHTTP] rpc server: hijack error:", err)
+		return
+	}
+	_, _ = io.WriteString(conn, "HTTP/1.0 "+connected+"\n\n")
+	server.ServeConn(conn)
+}
+
+// HandleHTTP 在 defaultRPCPath 上注册 server，配合 net/http.Serve 使用，
+// 让 DialHTTP/XDial 的 "http@" 分支能完成 CONNECT 握手。
+func (server *Server) HandleHTTP() {
+	http.Handle(defaultRPCPath, server)
+}
+
+// HandleHTTP 是 DefaultServer.HandleHTTP 的包级别快捷方式
+func HandleHTTP() {
+	DefaultServer.HandleHTTP()
+}
+
+// invalidRequest 在请求处理出错、但仍需要回写一个占位 body 时使用
+var invalidRequest = struct{}{}
+
+func (server *Server) serveCodec(cc codec.Codec) {
+	sending := new(sync.Mutex) // 保证回写响应时不会交叉写坏一次消息
+	wg := new(sync.WaitGroup)
+	for {
+		header, argv, err := server.readRequest(cc)
+		if err != nil {
+			if header == nil {
+				break // 连接已经不可恢复，无法再回写错误
+			}
+			header.Error = err.Error()
+			server.sendResponse(cc, header, invalidRequest, sending)
+			continue
+		}
+		wg.Add(1)
+		go server.handleRequest(cc, header, argv, sending, wg)
+	}
+	wg.Wait()
+}
+
+func (server *Server) readRequest(cc codec.Codec) (*codec.Header, interface{}, error) {
+	var h codec.Header
+	if err := cc.ReadHeader(&h); err != nil {
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Println("[readRequest] rpc server: read header error:", err)
+		}
+		return nil, nil, err
+	}
+
+	if server.auth != nil {
+		if err := server.auth.ValidateToken(&h); err != nil {
+			_ = cc.ReadBody(nil)
+			return &h, nil, err
+		}
+	}
+
+	server.mu.RLock()
+	rh, ok := server.handlers[h.ServiceMethod]
+	server.mu.RUnlock()
+	if !ok {
+		_ = cc.ReadBody(nil)
+		return &h, nil, fmt.Errorf("[readRequest] rpc server: can't find handler for %s", h.ServiceMethod)
+	}
+
+	argv := rh.newArg()
+	if err := cc.ReadBody(argv); err != nil {
+		log.Println("[readRequest] rpc server: read body error:", err)
+		return &h, nil, err
+	}
+	return &h, argv, nil
+}
+
+func (server *Server) handleRequest(cc codec.Codec, h *codec.Header, argv interface{}, sending *sync.Mutex, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	server.mu.RLock()
+	rh := server.handlers[h.ServiceMethod]
+	server.mu.RUnlock()
+
+	// newIncomingContext 把 Header.Metadata 交给 handler（通过 IncomingMetadata
+	// 读取），并让 Header.Deadline 驱动 ctx 的取消，handler 可以据此提前中止处理。
+	ctx, cancel := newIncomingContext(context.Background(), h)
+	defer cancel()
+
+	reply, err := rh.handler(ctx, argv)
+	if err != nil {
+		h.Error = err.Error()
+		server.sendResponse(cc, h, invalidRequest, sending)
+		return
+	}
+	server.sendResponse(cc, h, reply, sending)
+}
+
+func (server *Server) sendResponse(cc codec.Codec, h *codec.Header, body interface{}, sending *sync.Mutex) {
+	sending.Lock()
+	defer sending.Unlock()
+	if err := cc.Write(h, body); err != nil {
+		log.Println("[sendResponse] rpc server: write response error:", err)
+	}
+}
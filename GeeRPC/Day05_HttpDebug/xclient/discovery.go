@@ -0,0 +1,93 @@
+package xclient
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SelectMode 代表不同的负载均衡策略，目前只支持 Random 和 RoundRobin
+type SelectMode int
+
+const (
+	RandomSelect     SelectMode = iota // select randomly
+	RoundRobinSelect                   // select using Robin algorithm
+)
+
+// Discovery 是一个服务发现的接口，包含了服务发现所需要的最基本的接口。
+type Discovery interface {
+	Refresh() error // refresh from remote registry
+	Update(servers []string) error
+	Get(mode SelectMode) (string, error)
+	GetAll() ([]string, error)
+}
+
+/*
+MultiServersDiscovery 是一个不需要注册中心，服务列表由手工维护的服务发现的结构体。
+用户显式地提供服务器地址。
+*/
+type MultiServersDiscovery struct {
+	r       *rand.Rand // generate random number
+	mu      sync.RWMutex
+	servers []string
+	index   int // record the selected position for robin algorithm
+}
+
+// NewMultiServerDiscovery 创建一个 MultiServersDiscovery 实例
+func NewMultiServerDiscovery(servers []string) *MultiServersDiscovery {
+	d := &MultiServersDiscovery{
+		servers: servers,
+		r:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	// index 随机设置一个初始值，避免每次从 0 开始
+	d.index = d.r.Intn(math.MaxInt32 - 1)
+	return d
+}
+
+var _ Discovery = (*MultiServersDiscovery)(nil)
+
+// Refresh 对 MultiServersDiscovery 没有意义，服务列表由用户显式更新
+func (d *MultiServersDiscovery) Refresh() error {
+	return nil
+}
+
+// Update 动态更新发现到的服务列表
+func (d *MultiServersDiscovery) Update(servers []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.servers = servers
+	return nil
+}
+
+// Get 根据负载均衡策略，选择一个服务实例
+func (d *MultiServersDiscovery) Get(mode SelectMode) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.servers)
+	if n == 0 {
+		return "", errors.New("[Get] rpc discovery: no available servers")
+	}
+	switch mode {
+	case RandomSelect:
+		return d.servers[d.r.Intn(n)], nil
+	case RoundRobinSelect:
+		// servers could be updated, so mode n to ensure safety
+		s := d.servers[d.index%n]
+		d.index = (d.index + 1) % n
+		return s, nil
+	default:
+		return "", errors.New("[Get] rpc discovery: not supported select mode")
+	}
+}
+
+// GetAll 返回所有的服务实例
+func (d *MultiServersDiscovery) GetAll() ([]string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	// return a copy of d.servers
+	servers := make([]string, len(d.servers), len(d.servers))
+	copy(servers, d.servers)
+	return servers, nil
+}
@@ -0,0 +1,85 @@
+package xclient
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+GeeRegistryDiscovery 内嵌 MultiServersDiscovery，复用其 Get、GetAll 的方法。
+registry 为注册中心的地址，timeout 服务列表的过期时间，超过该时间则需要重新请求注册中心更新。
+*/
+type GeeRegistryDiscovery struct {
+	*MultiServersDiscovery
+	registry   string        // 注册中心地址
+	timeout    time.Duration // 服务列表的过期时间
+	lastUpdate time.Time     // 最后从注册中心更新服务列表的时间
+}
+
+// defaultUpdateTimeout 默认 10s 更新一次
+const defaultUpdateTimeout = 10 * time.Second
+
+// NewGeeRegistryDiscovery 创建一个 GeeRegistryDiscovery 实例
+func NewGeeRegistryDiscovery(registerAddr string, timeout time.Duration) *GeeRegistryDiscovery {
+	if timeout == 0 {
+		timeout = defaultUpdateTimeout
+	}
+	d := &GeeRegistryDiscovery{
+		MultiServersDiscovery: NewMultiServerDiscovery(make([]string, 0)),
+		registry:              registerAddr,
+		timeout:               timeout,
+	}
+	return d
+}
+
+// Update 手动更新服务列表，并刷新 lastUpdate
+func (d *GeeRegistryDiscovery) Update(servers []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.servers = servers
+	d.lastUpdate = time.Now()
+	return nil
+}
+
+// Refresh 超过 timeout 没有更新，则向注册中心请求新的服务列表
+func (d *GeeRegistryDiscovery) Refresh() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.lastUpdate.Add(d.timeout).After(time.Now()) {
+		return nil
+	}
+	log.Println("[Refresh] rpc registry: refresh servers from registry", d.registry)
+	resp, err := http.Get(d.registry)
+	if err != nil {
+		log.Println("[Refresh] rpc registry refresh err:", err)
+		return err
+	}
+	defer resp.Body.Close()
+	servers := strings.Split(resp.Header.Get("X-Geerpc-Servers"), ",")
+	d.servers = make([]string, 0, len(servers))
+	for _, server := range servers {
+		if strings.TrimSpace(server) != "" {
+			d.servers = append(d.servers, strings.TrimSpace(server))
+		}
+	}
+	d.lastUpdate = time.Now()
+	return nil
+}
+
+// Get 选择服务实例前先刷新一次，确保服务列表不是陈旧的
+func (d *GeeRegistryDiscovery) Get(mode SelectMode) (string, error) {
+	if err := d.Refresh(); err != nil {
+		return "", err
+	}
+	return d.MultiServersDiscovery.Get(mode)
+}
+
+// GetAll 获取所有服务实例前先刷新一次，确保服务列表不是陈旧的
+func (d *GeeRegistryDiscovery) GetAll() ([]string, error) {
+	if err := d.Refresh(); err != nil {
+		return nil, err
+	}
+	return d.MultiServersDiscovery.GetAll()
+}
@@ -0,0 +1,126 @@
+package xclient
+
+import (
+	"context"
+	"geerpc"
+	"io"
+	"reflect"
+	"sync"
+)
+
+/*
+XClient 是一个支持负载均衡的客户端，对 geerpc.Client 做了一层封装。
+d 是服务发现的实例，mode 是负载均衡模式，opt 是创建 geerpc.Client 所需的协议选项。
+clients 保存创建成功的 Client 实例，并默认启用了连接复用，在请求结束后不会关闭连接，
+以便下次请求时直接复用。
+*/
+type XClient struct {
+	d       Discovery
+	mode    SelectMode
+	opt     *geerpc.Option
+	mu      sync.Mutex // protect following
+	clients map[string]*geerpc.Client
+}
+
+var _ io.Closer = (*XClient)(nil)
+
+// NewXClient 创建一个 XClient 实例
+func NewXClient(d Discovery, mode SelectMode, opt *geerpc.Option) *XClient {
+	return &XClient{
+		d:       d,
+		mode:    mode,
+		opt:     opt,
+		clients: make(map[string]*geerpc.Client),
+	}
+}
+
+// Close 释放 XClient 持有的所有 Client：它们由 XDial 借自连接池，
+// client.Close 会把连接归还给连接池，而不是直接断开
+func (xc *XClient) Close() error {
+	xc.mu.Lock()
+	defer xc.mu.Unlock()
+	for key, client := range xc.clients {
+		// I have no idea how to deal with error, just ignore it.
+		_ = client.Close()
+		delete(xc.clients, key)
+	}
+	return nil
+}
+
+// dial 返回 rpcAddr 对应的 Client 实例，如果缓存中不存在或已不可用，则新建一个
+func (xc *XClient) dial(rpcAddr string) (*geerpc.Client, error) {
+	xc.mu.Lock()
+	defer xc.mu.Unlock()
+	client, ok := xc.clients[rpcAddr]
+	if ok && !client.IsAvailable() {
+		_ = client.Close()
+		delete(xc.clients, rpcAddr)
+		client = nil
+	}
+	if client == nil {
+		var err error
+		client, err = geerpc.XDial(rpcAddr, xc.opt)
+		if err != nil {
+			return nil, err
+		}
+		xc.clients[rpcAddr] = client
+	}
+	return client, nil
+}
+
+func (xc *XClient) call(rpcAddr string, ctx context.Context, serviceMethod string, args, reply interface{}) error {
+	client, err := xc.dial(rpcAddr)
+	if err != nil {
+		return err
+	}
+	return client.Call(ctx, serviceMethod, args, reply)
+}
+
+// Call 根据负载均衡策略选择一个服务实例，并发起调用
+func (xc *XClient) Call(ctx context.Context, serviceMethod string, args, reply interface{}) error {
+	rpcAddr, err := xc.d.Get(xc.mode)
+	if err != nil {
+		return err
+	}
+	return xc.call(rpcAddr, ctx, serviceMethod, args, reply)
+}
+
+/*
+Broadcast 将请求广播至发现到的所有服务实例，如果任意一个实例发生错误，
+则返回其中一个错误；如果调用成功，则返回其中一个的调用结果。
+*/
+func (xc *XClient) Broadcast(ctx context.Context, serviceMethod string, args, reply interface{}) error {
+	servers, err := xc.d.GetAll()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex // protect e and replyDone
+	var e error
+	replyDone := reply == nil // if reply is nil, don't need to set value
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for _, rpcAddr := range servers {
+		wg.Add(1)
+		go func(rpcAddr string) {
+			defer wg.Done()
+			var clonedReply interface{}
+			if reply != nil {
+				clonedReply = reflect.New(reflect.ValueOf(reply).Elem().Type()).Interface()
+			}
+			err := xc.call(rpcAddr, ctx, serviceMethod, args, clonedReply)
+			mu.Lock()
+			if err != nil && e == nil {
+				e = err
+				cancel() // if any call failed, cancel unfinished calls
+			}
+			if err == nil && !replyDone {
+				reflect.ValueOf(reply).Elem().Set(reflect.ValueOf(clonedReply).Elem())
+				replyDone = true
+			}
+			mu.Unlock()
+		}(rpcAddr)
+	}
+	wg.Wait()
+	return e
+}